@@ -0,0 +1,83 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/full/transformer"
+)
+
+// watchContractCmd is a wizard for the common case of wanting to index
+// everything a contract emits and exposes, without hand-writing a config
+// file enumerating its events and methods. Given just an address it
+// auto-subscribes to every event and every read-only method found in the
+// contract's abi.
+var watchContractCmd = &cobra.Command{
+	Use:   "watchContract",
+	Short: "Watch every event and poll every method on a single contract",
+	Long: `watchContract takes a contract address and, after confirming with the user
+(skippable with --yes), watches every event the contract emits and polls every
+read-only method in its abi. It is meant for quickly standing up a watcher
+without writing out a full contract_watcher config.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		watchContract()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchContractCmd)
+	watchContractCmd.Flags().String("address", "", "address of the contract to watch")
+	watchContractCmd.Flags().Bool("yes", false, "skip the confirmation prompt and watch everything")
+	if err := viper.BindPFlag("watch.address", watchContractCmd.Flags().Lookup("address")); err != nil {
+		logrus.Fatalf("error binding watch.address flag: %s", err.Error())
+	}
+	if err := viper.BindPFlag("watch.yes", watchContractCmd.Flags().Lookup("yes")); err != nil {
+		logrus.Fatalf("error binding watch.yes flag: %s", err.Error())
+	}
+}
+
+func watchContract() {
+	addr := viper.GetString("watch.address")
+	if addr == "" {
+		logrus.Fatal("watchContract requires --address")
+	}
+	skipConfirm := viper.GetBool("watch.yes")
+
+	con, err := transformer.AutoConfigure(addr, viper.GetString("network"), skipConfirm)
+	if err != nil {
+		logrus.Fatalf("error auto-configuring watcher: %s", err.Error())
+	}
+
+	blockChain := getBlockChain()
+	db := getDB()
+
+	tr := transformer.NewTransformer(con, blockChain, db)
+	if err := tr.Init(); err != nil {
+		logrus.Fatalf("error initializing watcher: %s", err.Error())
+	}
+
+	fmt.Print(tr.Summarize(addr))
+
+	if err := tr.Execute(); err != nil {
+		logrus.Fatalf("error executing watcher: %s", err.Error())
+	}
+}
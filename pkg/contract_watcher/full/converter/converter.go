@@ -0,0 +1,88 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package converter
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/contract"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
+// ConverterInterface converts a raw watched event log into the custom Log
+// type a Sink persists. Every call is given the contract info (abi, event
+// definitions) it needs directly, rather than relying on a prior call
+// having stashed it in mutable state, so a single ConverterInterface can be
+// driven by Execute's pool of converter workers concurrently.
+type ConverterInterface interface {
+	Convert(we core.WatchedEvent, event types.Event, con *contract.Contract) (*types.Log, error)
+}
+
+// Converter is the default ConverterInterface implementation. It holds no
+// per-contract state, so the zero value is safe to share across goroutines.
+type Converter struct{}
+
+// Convert decodes we's indexed and unindexed args according to event's
+// fields (sourced from con's parsed abi) into a types.Log. It returns a nil
+// Log, nil error when we doesn't actually match event (e.g. its topic0
+// doesn't line up), so callers can skip persisting it.
+func (Converter) Convert(we core.WatchedEvent, event types.Event, con *contract.Contract) (*types.Log, error) {
+	if we.Topic0 != event.Sig {
+		return nil, nil
+	}
+
+	topics := []string{we.Topic1, we.Topic2, we.Topic3}
+	nextTopic := 0
+
+	values := make(map[string]string, len(event.Fields))
+	if len(event.Fields) > 0 && len(con.ParsedAbi.Events) == 0 {
+		return nil, fmt.Errorf("converter: contract %s has no parsed abi to decode event %s against", con.Address, event.Name)
+	}
+
+	unpacked, err := con.ParsedAbi.Unpack(event.Name, common.FromHex(we.Data))
+	if err != nil {
+		return nil, fmt.Errorf("error unpacking event %s data: %w", event.Name, err)
+	}
+	unpackedIdx := 0
+
+	for _, field := range event.Fields {
+		if field.Indexed {
+			if nextTopic >= len(topics) {
+				return nil, fmt.Errorf("converter: event %s has more indexed fields than topics", event.Name)
+			}
+			values[field.Name] = topics[nextTopic]
+			nextTopic++
+			continue
+		}
+		if unpackedIdx >= len(unpacked) {
+			return nil, fmt.Errorf("converter: event %s has more unindexed fields than unpacked values", event.Name)
+		}
+		values[field.Name] = fmt.Sprintf("%v", unpacked[unpackedIdx])
+		unpackedIdx++
+	}
+
+	return &types.Log{
+		Address:     we.Address,
+		TxHash:      we.TxHash,
+		BlockNumber: we.BlockNumber,
+		Index:       we.LogID,
+		Values:      values,
+	}, nil
+}
@@ -0,0 +1,90 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package transformer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/makerdao/vulcanizedb/pkg/config"
+)
+
+// AutoConfigure builds a ContractConfig for addr that watches every event
+// and polls every read-only method the parser can discover in its abi,
+// instead of requiring the caller to enumerate them. It leaves Events and
+// Methods empty for addr and sets WatchAll[addr], which is what tells
+// Transformer.Init to treat that empty list as "all" rather than "none" -
+// an opt-in, so existing configs that happen to leave Events/Methods unset
+// aren't affected.
+//
+// Unless skipConfirm is set, it prompts on stdin before returning, since
+// subscribing to every event on an unfamiliar contract can mean indexing a
+// very large number of historical logs.
+func AutoConfigure(addr string, network string, skipConfirm bool) (config.ContractConfig, error) {
+	if !skipConfirm {
+		proceed := confirm(fmt.Sprintf(
+			"Warning: no events specified, proceed to watch every event and poll every method on %s? (Y/n) ", addr))
+		if !proceed {
+			return config.ContractConfig{}, errors.New("aborted: no events or methods selected")
+		}
+	}
+
+	return config.ContractConfig{
+		Network:        network,
+		Addresses:      map[string]string{addr: addr},
+		Abis:           map[string]string{},
+		StartingBlocks: map[string]int64{addr: 0},
+		Events:         map[string][]string{addr: {}},
+		Methods:        map[string][]string{addr: {}},
+		WatchAll:       map[string]bool{addr: true},
+	}, nil
+}
+
+// Summarize returns a human-readable listing of the events and methods a
+// transformer is configured to watch for addr, for printing to the user
+// before Execute starts
+func (tr *Transformer) Summarize(addr string) string {
+	con, ok := tr.Contracts[addr]
+	if !ok {
+		return fmt.Sprintf("%s: not initialized", addr)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Watching %s (%s):\n", addr, con.Name)
+	fmt.Fprintf(&b, "  %d event(s):\n", len(con.Events))
+	for _, event := range con.Events {
+		fmt.Fprintf(&b, "    - %s\n", event.Name)
+	}
+	fmt.Fprintf(&b, "  %d method(s):\n", len(con.Methods))
+	for name := range con.Methods {
+		fmt.Fprintf(&b, "    - %s\n", name)
+	}
+	return b.String()
+}
+
+// confirm prints prompt and reads a yes/no answer from stdin, defaulting to
+// yes on an empty answer
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
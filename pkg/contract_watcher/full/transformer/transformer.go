@@ -18,6 +18,10 @@ package transformer
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -27,7 +31,7 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/contract"
 	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/parser"
 	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/poller"
-	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/repository"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/sink"
 	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
@@ -39,16 +43,21 @@ import (
 // Requires a fully synced vDB and a running eth node (or infura)
 type Transformer struct {
 	// Database interfaces
-	FilterRepository           datastore.FilterRepository       // Log filters repo; accepts filters generated by Contract.GenerateFilters()
-	WatchedEventRepository     datastore.WatchedEventRepository // Watched event log views, created by the log filters
-	TransformedEventRepository repository.EventRepository       // Holds transformed watched event log data
+	FilterRepository       datastore.FilterRepository       // Log filters repo; accepts filters generated by Contract.GenerateFilters()
+	WatchedEventRepository datastore.WatchedEventRepository // Watched event log views, created by the log filters
+
+	// Sinks transformed events and polled method results are fanned out to.
+	// Postgres is always included by NewTransformer; additional sinks (e.g.
+	// Kafka, NATS JetStream, stdout) can be appended to watch the same data
+	// flow to a message bus at the same time
+	Sinks []sink.Sink
 
 	// Pre-processing interfaces
 	Parser    parser.Parser            // Parses events and methods out of contract abi fetched using contract address
 	Retriever retriever.BlockRetriever // Retrieves first block for contract and current block height
 
 	// Processing interfaces
-	Converter converter.ConverterInterface // Converts watched event logs into custom log
+	Converter converter.ConverterInterface // Converts watched event logs into custom log; must be safe for concurrent use, since Execute calls it from a pool of converter workers
 	Poller    poller.Poller                // Polls methods using contract's token holder addresses and persists them using method datastore
 
 	// Store contract configuration information
@@ -61,18 +70,21 @@ type Transformer struct {
 	LastBlock int64
 }
 
-// NewTransformer takes in contract config, blockchain, and database, and returns a new Transformer
-func NewTransformer(con config.ContractConfig, BC core.BlockChain, DB *postgres.DB) *Transformer {
+// NewTransformer takes in contract config, blockchain, and database, and
+// returns a new Transformer. The postgres sink backed by DB is always
+// included; extraSinks are appended so the same transformed data can be
+// fanned out to a message bus (Kafka, NATS) or stdout at the same time
+func NewTransformer(con config.ContractConfig, BC core.BlockChain, DB *postgres.DB, extraSinks ...sink.Sink) *Transformer {
 	return &Transformer{
-		Poller:                     poller.NewPoller(BC, DB, types.FullSync),
-		Parser:                     parser.NewParser(con.Network),
-		Retriever:                  retriever.NewBlockRetriever(DB),
-		Converter:                  &converter.Converter{},
-		Contracts:                  map[string]*contract.Contract{},
-		WatchedEventRepository:     repositories.WatchedEventRepository{DB: DB},
-		FilterRepository:           repositories.FilterRepository{DB: DB},
-		TransformedEventRepository: repository.NewEventRepository(DB, types.FullSync),
-		Config:                     con,
+		Poller:                 poller.NewPoller(BC, types.FullSync),
+		Parser:                 parser.NewParser(con.Network),
+		Retriever:              retriever.NewBlockRetriever(DB),
+		Converter:              &converter.Converter{},
+		Contracts:              map[string]*contract.Contract{},
+		WatchedEventRepository: repositories.WatchedEventRepository{DB: DB},
+		FilterRepository:       repositories.FilterRepository{DB: DB},
+		Sinks:                  append([]sink.Sink{sink.NewPostgresSink(DB, types.FullSync)}, extraSinks...),
+		Config:                 con,
 	}
 }
 
@@ -81,18 +93,54 @@ func NewTransformer(con config.ContractConfig, BC core.BlockChain, DB *postgres.
 // Loops over all of the addr => filter sets
 // Uses parser to pull event info from abi
 // Use this info to generate event filters
+//
+// Addresses that share an ABI can be declared once in Config.AbiGroups
+// (keyed by either the raw ABI string or a proxy's implementation address)
+// instead of being fetched individually, so hundreds of near-identical
+// deployments (e.g. ERC-20/721 tokens) don't each pay for their own
+// etherscan lookup. The group's abi string is cached and reloaded into the
+// parser's single internal Abi whenever another abi was loaded in between
 func (tr *Transformer) Init() error {
-	for contractAddr := range tr.Config.Addresses {
-		// Configure Abi
-		if tr.Config.Abis[contractAddr] == "" {
-			// If no abi is given in the config, this method will try fetching from internal look-up table and etherscan
-			err := tr.Parser.Parse(contractAddr)
-			if err != nil {
-				return err
+	addrToGroupKey := tr.mapAddressesToAbiGroups()
+	groupAbis := map[string]string{}
+
+	for contractAddr := range tr.allConfiguredAddresses(addrToGroupKey) {
+		groupKey, inGroup := addrToGroupKey[contractAddr]
+
+		// Configure Abi; addresses sharing a group only need it fetched once
+		if !inGroup || groupAbis[groupKey] == "" {
+			abiSource := tr.Config.Abis[contractAddr]
+			if inGroup {
+				abiSource = groupKey
 			}
-		} else {
-			// If we have an abi from the config, load that into the parser
-			err := tr.Parser.ParseAbiStr(tr.Config.Abis[contractAddr])
+
+			if abiSource == "" {
+				// If no abi is given in the config, this method fetches it from etherscan
+				err := tr.Parser.Parse(contractAddr)
+				if err != nil {
+					return err
+				}
+			} else if looksLikeAddress(abiSource) {
+				// A group keyed by a proxy's implementation address: fetch its abi
+				err := tr.Parser.Parse(abiSource)
+				if err != nil {
+					return err
+				}
+			} else {
+				// If we have an abi from the config, load that into the parser
+				err := tr.Parser.ParseAbiStr(abiSource)
+				if err != nil {
+					return err
+				}
+			}
+
+			if inGroup {
+				groupAbis[groupKey] = tr.Parser.Abi()
+			}
+		} else if tr.Parser.Abi() != groupAbis[groupKey] {
+			// Another abi was loaded since this group's; reload the group's
+			// cached abi string without fetching it again
+			err := tr.Parser.ParseAbiStr(groupAbis[groupKey])
 			if err != nil {
 				return err
 			}
@@ -126,22 +174,51 @@ func (tr *Transformer) Init() error {
 			methodArgs[arg] = true
 		}
 
-		// Aggregate info into contract object
+		// An empty Events/Methods list normally means "watch nothing yet
+		// configured" for this address. Only contractAddr's explicitly
+		// opting into Config.WatchAll - set by AutoConfigure, never implied
+		// by an empty list on its own - are treated as "watch everything";
+		// otherwise a pre-existing methods-only config that leaves Events
+		// unset keeps watching no events, instead of silently picking up
+		// every event in the abi.
+		watchAll := tr.Config.WatchAll[contractAddr]
+		events := tr.Config.Events[contractAddr]
+		var contractEvents map[string]types.Event
+		if len(events) == 0 && watchAll {
+			contractEvents = tr.Parser.GetAllEvents()
+		} else {
+			contractEvents = tr.Parser.GetEvents(events)
+		}
+		methods := tr.Config.Methods[contractAddr]
+		var contractMethods map[string]types.Method
+		if len(methods) == 0 && watchAll {
+			contractMethods = tr.Parser.GetAllSelectMethods()
+		} else {
+			contractMethods = tr.Parser.GetSelectMethods(methods)
+		}
+
+		// Aggregate info into contract object. Addresses carries every
+		// address that shares this contract's abi group (just the address
+		// itself when it isn't part of a group), so GenerateFilters can
+		// produce a single address-set filter that covers the whole group
+		// instead of one filter per address
 		info := contract.Contract{
 			Name:          *name,
 			Network:       tr.Config.Network,
 			Address:       contractAddr,
+			Addresses:     tr.groupAddresses(contractAddr, groupKey, inGroup),
 			Abi:           tr.Parser.Abi(),
 			ParsedAbi:     tr.Parser.ParsedAbi(),
 			StartingBlock: firstBlock,
-			Events:        tr.Parser.GetEvents(tr.Config.Events[contractAddr]),
-			Methods:       tr.Parser.GetSelectMethods(tr.Config.Methods[contractAddr]),
+			Events:        contractEvents,
+			Methods:       contractMethods,
 			FilterArgs:    eventArgs,
 			MethodArgs:    methodArgs,
 			Piping:        tr.Config.Piping[contractAddr],
 		}.Init()
 
-		// Use info to create filters
+		// Use info to create filters; filters carrying more than one address
+		// subscribe a single log filter to the whole group
 		err = info.GenerateFilters()
 		if err != nil {
 			return err
@@ -169,53 +246,150 @@ func (tr *Transformer) Init() error {
 	return nil
 }
 
+// rawWatchedEvent pairs a watched event log with the contract/event it was
+// fetched for, so a converter worker that pulls it off the shared channel
+// still knows how to convert and where to persist it
+type rawWatchedEvent struct {
+	we    *core.WatchedEvent
+	event types.Event
+	con   *contract.Contract
+}
+
+// convertedLog is a converted log tagged with the event/address/name
+// PersistLogs needs, so the persister can batch logs for the same
+// (address, event) pair together regardless of which converter worker
+// produced them
+type convertedLog struct {
+	log     types.Log
+	event   types.Event
+	address string
+	name    string
+}
+
 // Execute runs the transformation processes
-// Iterates through stored, initialized contract objects
-// Iterates through contract's event filters, grabbing watched event logs
-// Uses converter to convert logs into custom log type
-// Persists converted logs into custom postgres tables
+// Iterates through stored, initialized contract objects and, for each of
+// their event filters, fans out a producer goroutine that streams watched
+// event logs onto a shared channel. A pool of converter workers (sized by
+// Config.Concurrency) drains that channel, converts each log, and hands it
+// to a batching persister that accumulates up to Config.BatchSize logs (or
+// batchWindow, whichever comes first) per (address, event) pair before
+// calling PersistLogs, so high-volume contracts don't pay for one
+// transaction per row.
 // Calls selected methods, using token holder address generated during event log conversion
 func (tr *Transformer) Execute() error {
 	if len(tr.Contracts) == 0 {
 		return errors.New("error: transformer has no initialized contracts to work with")
 	}
-	// Iterate through all internal contracts
-	for _, con := range tr.Contracts {
-		// Update converter with current contract
-		tr.Converter.Update(con)
 
-		// Iterate through contract filters and get watched event logs
-		for eventSig, filter := range con.Filters {
-			watchedEvents, err := tr.WatchedEventRepository.GetWatchedEvents(filter.Name)
-			if err != nil {
-				return err
-			}
+	concurrency := tr.Config.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+	batchSize := tr.Config.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
 
-			// Iterate over watched event logs
-			for _, we := range watchedEvents {
-				// Convert them to our custom log type
-				cstm, err := tr.Converter.Convert(*we, con.Events[eventSig])
+	rawEvents := make(chan rawWatchedEvent, concurrency*batchSize)
+	converted := make(chan convertedLog, concurrency*batchSize)
+
+	// reportErr collects every error raised by producers, converters, or
+	// the batch persister - not just the first - so a failure on one
+	// (contract, eventSig) or one sink doesn't silently hide failures on
+	// the others. Each is logged as it arrives, since on a high-volume
+	// run Execute may not return for a while yet.
+	var errsMu sync.Mutex
+	var errs []error
+	reportErr := func(err error) {
+		logrus.Errorf("error during execute: %s", err.Error())
+		errsMu.Lock()
+		errs = append(errs, err)
+		errsMu.Unlock()
+	}
+
+	var producers sync.WaitGroup
+	for _, con := range tr.Contracts {
+		for eventSig, filter := range con.Filters {
+			producers.Add(1)
+			go func(con *contract.Contract, eventSig string, filter contract.Filter) {
+				defer producers.Done()
+				watchedEvents, err := tr.WatchedEventRepository.GetWatchedEvents(filter.Name)
 				if err != nil {
-					return err
+					reportErr(err)
+					return
 				}
-				if cstm == nil {
-					continue
+				for _, we := range watchedEvents {
+					rawEvents <- rawWatchedEvent{we: we, event: con.Events[eventSig], con: con}
 				}
-
-				// If log is not empty, immediately persist in repo
-				// Run this in seperate goroutine?
-				err = tr.TransformedEventRepository.PersistLogs([]types.Log{*cstm}, con.Events[eventSig], con.Address, con.Name)
+			}(con, eventSig, filter)
+		}
+	}
+	go func() {
+		producers.Wait()
+		close(rawEvents)
+	}()
+
+	var converters sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		converters.Add(1)
+		go func() {
+			defer converters.Done()
+			for raw := range rawEvents {
+				// raw.con is passed explicitly on every call (instead of a
+				// prior call to Converter.Update mutating shared state) so
+				// Converter is safe to drive from multiple workers at once.
+				// we.Address, not con.Address, is used downstream so rows
+				// coming from a grouped, multi-address filter are tagged
+				// with the address that actually emitted them
+				cstm, err := tr.Converter.Convert(*raw.we, raw.event, raw.con)
 				if err != nil {
-					return err
+					reportErr(err)
+					continue
+				}
+				if cstm == nil {
+					continue
 				}
+				converted <- convertedLog{log: *cstm, event: raw.event, address: raw.we.Address, name: raw.con.Name}
 			}
-		}
+		}()
+	}
+	go func() {
+		converters.Wait()
+		close(converted)
+	}()
 
-		// After persisting all watched event logs
-		// poller polls select contract methods
-		// and persists the results into custom pg tables
-		if err := tr.Poller.PollContract(*con, tr.LastBlock); err != nil {
-			return err
+	if err := tr.batchPersist(converted, batchSize, defaultBatchWindow, reportErr); err != nil {
+		return err
+	}
+
+	errsMu.Lock()
+	numErrs := len(errs)
+	firstErr := error(nil)
+	if numErrs > 0 {
+		firstErr = errs[0]
+	}
+	errsMu.Unlock()
+	if numErrs > 0 {
+		return fmt.Errorf("execute: %d error(s) occurred, first: %w", numErrs, firstErr)
+	}
+
+	// After persisting all watched event logs, poller polls select contract
+	// methods for every address in the group (just con.Address when it
+	// isn't part of a group). The Poller only decodes results - fanning
+	// them out to every configured Sink (not just postgres) is done here,
+	// same as the converted event logs above
+	for _, con := range tr.Contracts {
+		for _, addr := range con.Addresses {
+			results, err := tr.Poller.PollContract(con.ForAddress(addr), tr.LastBlock)
+			if err != nil {
+				return err
+			}
+			if len(results) == 0 {
+				continue
+			}
+			if err := tr.persistMethodResultsToSinks(results); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -234,3 +408,167 @@ func (tr *Transformer) Execute() error {
 func (tr *Transformer) GetConfig() config.ContractConfig {
 	return tr.Config
 }
+
+const (
+	// defaultConcurrency is the number of converter workers Execute spins up
+	// when Config.Concurrency is unset
+	defaultConcurrency = 4
+	// defaultBatchSize is the max number of logs the persister accumulates
+	// for a single (address, event) pair before flushing, when
+	// Config.BatchSize is unset
+	defaultBatchSize = 500
+	// defaultBatchWindow bounds how long a partial batch waits for more logs
+	// before being flushed anyway, so low-volume contracts aren't held up
+	// behind a batch that will never fill
+	defaultBatchWindow = 2 * time.Second
+)
+
+// persistKey identifies one of the batches batchPersist accumulates logs
+// into; logs only ever get batched together when they share all three
+type persistKey struct {
+	address string
+	name    string
+	sig     string
+}
+
+// batchPersist drains converted, grouping logs by (address, event, name),
+// and flushes each group's batch via PersistLogs once it reaches batchSize
+// or on the next tick of a ticker firing every batchWindow, whichever comes
+// first, so no log waits longer than batchWindow. It returns once converted
+// is closed and every batch has been flushed.
+func (tr *Transformer) batchPersist(converted <-chan convertedLog, batchSize int, batchWindow time.Duration, reportErr func(error)) error {
+	batches := map[persistKey][]types.Log{}
+	events := map[persistKey]types.Event{}
+
+	flush := func(key persistKey) {
+		logs := batches[key]
+		if len(logs) == 0 {
+			return
+		}
+		if err := tr.persistToSinks(logs, events[key], key.address, key.name); err != nil {
+			reportErr(err)
+		}
+		batches[key] = nil
+	}
+
+	ticker := time.NewTicker(batchWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case cl, ok := <-converted:
+			if !ok {
+				for key := range batches {
+					flush(key)
+				}
+				return nil
+			}
+			key := persistKey{address: cl.address, name: cl.name, sig: cl.event.Name}
+			events[key] = cl.event
+			batches[key] = append(batches[key], cl.log)
+			if len(batches[key]) >= batchSize {
+				flush(key)
+			}
+		case <-ticker.C:
+			for key := range batches {
+				flush(key)
+			}
+		}
+	}
+}
+
+// maxSinkRetries bounds how many times persistToSinks retries a single
+// sink's PersistLogs call before giving up on it for this batch
+const maxSinkRetries = 3
+
+// persistToSinks writes an already-converted batch of logs to every
+// configured sink, one sink after another. A sink that fails is retried on
+// its own, up to maxSinkRetries times, without re-converting the logs or
+// resending them to sinks that already succeeded; if it still fails its
+// error is included in the returned error so the caller can report it, but
+// delivery to the remaining sinks still proceeds.
+func (tr *Transformer) persistToSinks(logs []types.Log, event types.Event, address, name string) error {
+	var errs []error
+	for _, s := range tr.Sinks {
+		var err error
+		for attempt := 0; attempt <= maxSinkRetries; attempt++ {
+			err = s.PersistLogs(logs, event, address, name)
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("error persisting to %d sink(s): %v", len(errs), errs)
+}
+
+// persistMethodResultsToSinks writes a batch of polled method call results
+// to every configured sink, with the same per-sink retry behavior as
+// persistToSinks
+func (tr *Transformer) persistMethodResultsToSinks(results []types.Result) error {
+	var errs []error
+	for _, s := range tr.Sinks {
+		var err error
+		for attempt := 0; attempt <= maxSinkRetries; attempt++ {
+			err = s.PersistMethodResults(results)
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("error persisting method results to %d sink(s): %v", len(errs), errs)
+}
+
+// mapAddressesToAbiGroups inverts Config.AbiGroups into an address => group
+// key lookup, so each address can be resolved to the abi it shares with the
+// rest of its group in constant time
+func (tr *Transformer) mapAddressesToAbiGroups() map[string]string {
+	addrToGroupKey := map[string]string{}
+	for groupKey, addrs := range tr.Config.AbiGroups {
+		for _, addr := range addrs {
+			addrToGroupKey[addr] = groupKey
+		}
+	}
+	return addrToGroupKey
+}
+
+// allConfiguredAddresses returns the union of Config.Addresses and every
+// address declared under Config.AbiGroups, so addresses only need to be
+// listed once, in whichever of the two maps is most convenient
+func (tr *Transformer) allConfiguredAddresses(addrToGroupKey map[string]string) map[string]bool {
+	all := map[string]bool{}
+	for addr := range tr.Config.Addresses {
+		all[addr] = true
+	}
+	for addr := range addrToGroupKey {
+		all[addr] = true
+	}
+	return all
+}
+
+// groupAddresses returns the full set of addresses that share contractAddr's
+// abi group, or just contractAddr itself when it isn't grouped
+func (tr *Transformer) groupAddresses(contractAddr, groupKey string, inGroup bool) []string {
+	if !inGroup {
+		return []string{contractAddr}
+	}
+	return tr.Config.AbiGroups[groupKey]
+}
+
+// looksLikeAddress reports whether an AbiGroups key is a proxy contract
+// address (to be resolved through the parser's etherscan path) rather than
+// a raw ABI string
+func looksLikeAddress(abiGroupKey string) bool {
+	return len(abiGroupKey) == 42 && strings.HasPrefix(abiGroupKey, "0x")
+}
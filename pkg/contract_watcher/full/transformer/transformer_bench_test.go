@@ -0,0 +1,170 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package transformer
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/full/converter"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/contract"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/sink"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
+// erc20TransferAbi is a minimal single-event abi, just enough for
+// Converter.Convert to actually unpack benchTransformer's logs instead of
+// failing on a zero-value ParsedAbi.
+const erc20TransferAbi = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+
+// fakeWatchedEventRepository hands back a fixed, in-memory slice of watched
+// events for every filter name, so the benchmark exercises the converter
+// worker pool and batching persister without a real database.
+type fakeWatchedEventRepository struct {
+	events []*core.WatchedEvent
+}
+
+func (f fakeWatchedEventRepository) GetWatchedEvents(name string) ([]*core.WatchedEvent, error) {
+	return f.events, nil
+}
+
+// countingSink is a Sink that just counts the logs it's handed, so it
+// doesn't add its own I/O cost to the benchmark.
+type countingSink struct {
+	mu   sync.Mutex
+	logs int
+}
+
+func (c *countingSink) PersistLogs(logs []types.Log, event types.Event, address, name string) error {
+	c.mu.Lock()
+	c.logs += len(logs)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *countingSink) PersistMethodResults(results []types.Result) error { return nil }
+func (c *countingSink) Close() error                                      { return nil }
+
+// noopPoller is a poller.Poller that does nothing, so the benchmark
+// measures Execute's producer/converter/persister pipeline in isolation
+// from the (unrelated to this request) method-polling path.
+type noopPoller struct{}
+
+func (noopPoller) FetchContractData(abiStr, contractAddr, method string, methodArg interface{}, result interface{}, lastBlock int64) error {
+	return nil
+}
+
+func (noopPoller) PollContract(con contract.Contract, lastBlock int64) ([]types.Result, error) {
+	return nil, nil
+}
+
+// fixedRetriever is a retriever.BlockRetriever that reports block 0 for
+// everything, so Execute's end-of-cycle block refresh needs no database.
+type fixedRetriever struct{}
+
+func (fixedRetriever) RetrieveFirstBlock(contractAddr string) (int64, error) { return 0, nil }
+func (fixedRetriever) RetrieveMostRecentBlock() (int64, error)               { return 0, nil }
+
+// benchTransformer builds a Transformer with numLogs historical Transfer
+// logs spread across one contract's single event filter, backed by a real
+// parsed abi (so Converter.Convert actually decodes them), a counting sink,
+// a no-op poller, and a fixed retriever.
+func benchTransformer(concurrency, batchSize, numLogs int) (*Transformer, *countingSink) {
+	parsedAbi, err := abi.JSON(strings.NewReader(erc20TransferAbi))
+	if err != nil {
+		panic(err)
+	}
+	transferEvent := parsedAbi.Events["Transfer"]
+	data, err := transferEvent.Inputs.NonIndexed().Pack(big.NewInt(1000))
+	if err != nil {
+		panic(err)
+	}
+
+	events := make([]*core.WatchedEvent, numLogs)
+	for i := 0; i < numLogs; i++ {
+		events[i] = &core.WatchedEvent{
+			Address:     "0xabc",
+			TxHash:      fmt.Sprintf("0x%d", i),
+			BlockNumber: int64(i),
+			LogID:       int64(i),
+			Topic0:      transferEvent.ID.Hex(),
+			Topic1:      "0xfrom",
+			Topic2:      "0xto",
+			Data:        hexutil.Encode(data),
+		}
+	}
+
+	con := contract.Contract{
+		Name:      "Bench",
+		Address:   "0xabc",
+		ParsedAbi: parsedAbi,
+		Events: map[string]types.Event{
+			transferEvent.ID.Hex(): {
+				Name: "Transfer",
+				Sig:  transferEvent.ID.Hex(),
+				Fields: []types.Field{
+					{Name: "from", Type: "address", Indexed: true},
+					{Name: "to", Type: "address", Indexed: true},
+					{Name: "value", Type: "uint256"},
+				},
+			},
+		},
+		Filters: map[string]contract.Filter{
+			transferEvent.ID.Hex(): {Name: "bench_Transfer", Addresses: []string{"0xabc"}},
+		},
+	}.Init()
+
+	countingS := &countingSink{}
+	return &Transformer{
+		Config:                 config.ContractConfig{Concurrency: concurrency, BatchSize: batchSize},
+		Contracts:              map[string]*contract.Contract{"0xabc": con},
+		WatchedEventRepository: fakeWatchedEventRepository{events: events},
+		Converter:              &converter.Converter{},
+		Poller:                 noopPoller{},
+		Retriever:              fixedRetriever{},
+		Sinks:                  []sink.Sink{countingS},
+	}, countingS
+}
+
+// BenchmarkExecute_LowConcurrency and BenchmarkExecute_HighConcurrency
+// demonstrate the throughput gain the worker pool redesign was meant to
+// provide: the same number of historical logs processed by a small vs.
+// large converter pool.
+func BenchmarkExecute_LowConcurrency(b *testing.B) {
+	benchmarkExecute(b, 1, 100)
+}
+
+func BenchmarkExecute_HighConcurrency(b *testing.B) {
+	benchmarkExecute(b, 8, 100)
+}
+
+func benchmarkExecute(b *testing.B, concurrency, batchSize int) {
+	for i := 0; i < b.N; i++ {
+		tr, _ := benchTransformer(concurrency, batchSize, 10000)
+		if err := tr.Execute(); err != nil {
+			b.Fatalf("execute: %s", err)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package transformer
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/full/converter"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/contract"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/parser"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/sink"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+)
+
+// Transformer is the light-sync counterpart to full/transformer.Transformer.
+// Where the full transformer reads from log filter views that require a
+// fully synced vDB, this Transformer walks headers directly from a
+// core.BlockChain (e.g. a remote/light node such as Infura), fetches
+// receipts, and filters logs for the watched contracts in-process. It does
+// not call FilterRepository.CreateFilter at all, so it can run against a
+// node that vDB has never synced headers for.
+type Transformer struct {
+	// Blockchain interface used to walk headers and fetch receipts directly
+	BlockChain core.BlockChain
+
+	// Database interfaces
+	CheckpointRepository datastore.CheckpointRepository // Tracks the last block processed per contract, so restarts resume instead of re-scanning
+
+	// Sinks transformed events are fanned out to; Postgres is always
+	// included by NewTransformer
+	Sinks []sink.Sink
+
+	// Pre-processing interfaces
+	Parser parser.Parser // Parses events and methods out of contract abi fetched using contract address
+
+	// Processing interfaces
+	Converter converter.ConverterInterface // Converts watched event logs into custom log
+
+	// Store contract configuration information
+	Config config.ContractConfig
+
+	// Store contract info as mapping to contract address
+	Contracts map[string]*contract.Contract
+}
+
+// NewTransformer takes in contract config, blockchain, and database, and
+// returns a new light Transformer. The postgres sink backed by DB is always
+// included; extraSinks are appended so the same transformed data can be
+// fanned out to a message bus (Kafka, NATS) or stdout at the same time
+func NewTransformer(con config.ContractConfig, BC core.BlockChain, DB *postgres.DB, extraSinks ...sink.Sink) *Transformer {
+	return &Transformer{
+		BlockChain:           BC,
+		Parser:               parser.NewParser(con.Network),
+		Converter:            &converter.Converter{},
+		Contracts:            map[string]*contract.Contract{},
+		CheckpointRepository: repositories.CheckpointRepository{DB: DB},
+		Sinks:                append([]sink.Sink{sink.NewPostgresSink(DB, types.LightSync)}, extraSinks...),
+		Config:               con,
+	}
+}
+
+// Init initializes the transformer
+// Use after creating and setting transformer
+// Loops over all of the addr => filter sets, same as full/transformer.Init,
+// but resolves the starting block from the checkpoint repository instead of
+// a header repository, and never generates or persists log filters
+func (tr *Transformer) Init() error {
+	for contractAddr := range tr.Config.Addresses {
+		// Configure Abi
+		if tr.Config.Abis[contractAddr] == "" {
+			// If no abi is given in the config, this method fetches it from etherscan
+			err := tr.Parser.Parse(contractAddr)
+			if err != nil {
+				return err
+			}
+		} else {
+			// If we have an abi from the config, load that into the parser
+			err := tr.Parser.ParseAbiStr(tr.Config.Abis[contractAddr])
+			if err != nil {
+				return err
+			}
+		}
+
+		// Resume from the last checkpointed block if we have one and it is
+		// further along than the configured starting block
+		startingBlock := tr.Config.StartingBlocks[contractAddr]
+		checkpoint, err := tr.CheckpointRepository.GetCheckpoint(contractAddr)
+		if err != nil {
+			return err
+		}
+		if checkpoint > startingBlock {
+			startingBlock = checkpoint
+		}
+
+		// Remove any potential accidental duplicate inputs in arg filter values
+		eventArgs := map[string]bool{}
+		for _, arg := range tr.Config.EventArgs[contractAddr] {
+			eventArgs[arg] = true
+		}
+		methodArgs := map[string]bool{}
+		for _, arg := range tr.Config.MethodArgs[contractAddr] {
+			methodArgs[arg] = true
+		}
+
+		// Aggregate info into contract object; no filters are generated since
+		// this transformer filters logs in-process rather than relying on
+		// log filter views
+		info := contract.Contract{
+			Network:       tr.Config.Network,
+			Address:       contractAddr,
+			Abi:           tr.Parser.Abi(),
+			ParsedAbi:     tr.Parser.ParsedAbi(),
+			StartingBlock: startingBlock,
+			Events:        tr.Parser.GetEvents(tr.Config.Events[contractAddr]),
+			FilterArgs:    eventArgs,
+			MethodArgs:    methodArgs,
+			Piping:        tr.Config.Piping[contractAddr],
+		}.Init()
+
+		tr.Contracts[contractAddr] = info
+	}
+
+	return nil
+}
+
+// Execute runs the light-sync transformation process
+// Walks headers once from the earliest of every watched contract's
+// StartingBlock up to the chain's current block, fetching each block's
+// receipts at most once no matter how many contracts are watched, since
+// this is meant to run against a metered remote node where repeating that
+// RPC work per contract isn't free. For each block, every contract whose
+// StartingBlock has been reached filters the shared receipts down to the
+// logs it emitted with a topic0 matching one of its watched events,
+// converts them, and persists the results. After a block is processed for
+// a contract, that contract's checkpoint is advanced so a restart resumes
+// from there instead of from StartingBlock.
+func (tr *Transformer) Execute() error {
+	if len(tr.Contracts) == 0 {
+		return errors.New("error: transformer has no initialized contracts to work with")
+	}
+
+	currentBlock, err := tr.BlockChain.LastBlock()
+	if err != nil {
+		return err
+	}
+
+	topic0sByAddress := make(map[string]map[common.Hash]string, len(tr.Contracts))
+	startingBlock := int64(-1)
+	for addr, con := range tr.Contracts {
+		topic0s := make(map[common.Hash]string, len(con.Events))
+		for sig := range con.Events {
+			topic0s[common.HexToHash(sig)] = sig
+		}
+		topic0sByAddress[addr] = topic0s
+		if startingBlock == -1 || con.StartingBlock < startingBlock {
+			startingBlock = con.StartingBlock
+		}
+	}
+
+	for blockNumber := startingBlock; blockNumber <= currentBlock.Int64(); blockNumber++ {
+		header, headerErr := tr.BlockChain.GetHeaderByNumber(blockNumber)
+		if headerErr != nil {
+			return headerErr
+		}
+
+		var receipts gethTypes.Receipts
+		var receiptsFetched bool
+		for addr, con := range tr.Contracts {
+			if blockNumber < con.StartingBlock {
+				continue
+			}
+
+			if !receiptsFetched {
+				var receiptsErr error
+				receipts, receiptsErr = tr.BlockChain.GetBlockReceipts(header.Hash)
+				if receiptsErr != nil {
+					return receiptsErr
+				}
+				receiptsFetched = true
+			}
+
+			if err := tr.processReceipts(con, receipts, topic0sByAddress[addr]); err != nil {
+				return err
+			}
+
+			if err := tr.CheckpointRepository.SetCheckpoint(con.Address, blockNumber); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// processReceipts filters the logs in a set of receipts down to those
+// emitted by con.Address with a topic0 matching one of con's watched
+// events, converts each match, and persists it
+func (tr *Transformer) processReceipts(con *contract.Contract, receipts gethTypes.Receipts, topic0s map[common.Hash]string) error {
+	contractAddr := common.HexToAddress(con.Address)
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if log.Address != contractAddr || len(log.Topics) == 0 {
+				continue
+			}
+			eventSig, watched := topic0s[log.Topics[0]]
+			if !watched {
+				continue
+			}
+
+			cstm, err := tr.Converter.Convert(core.WatchedEvent{
+				LogID:       int64(log.Index),
+				Address:     log.Address.Hex(),
+				TxHash:      log.TxHash.Hex(),
+				BlockNumber: int64(log.BlockNumber),
+				Topic0:      log.Topics[0].Hex(),
+				Topic1:      topicOrEmpty(log.Topics, 1),
+				Topic2:      topicOrEmpty(log.Topics, 2),
+				Topic3:      topicOrEmpty(log.Topics, 3),
+				Data:        common.Bytes2Hex(log.Data),
+			}, con.Events[eventSig], con)
+			if err != nil {
+				return err
+			}
+			if cstm == nil {
+				continue
+			}
+
+			for _, s := range tr.Sinks {
+				if err := s.PersistLogs([]types.Log{*cstm}, con.Events[eventSig], con.Address, con.Name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func topicOrEmpty(topics []common.Hash, i int) string {
+	if i >= len(topics) {
+		return ""
+	}
+	return topics[i].Hex()
+}
+
+// GetConfig returns the transformers config; satisfies the transformer interface
+func (tr *Transformer) GetConfig() config.ContractConfig {
+	return tr.Config
+}
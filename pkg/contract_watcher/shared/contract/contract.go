@@ -0,0 +1,87 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package contract
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+)
+
+// Contract aggregates everything a transformer needs to watch a contract:
+// its abi, the events/methods it should watch, and (once GenerateFilters
+// has run) the filters generated from them. Addresses holds every address
+// that shares this Contract's abi group (just Address itself when it isn't
+// part of a group, e.g. via Config.AbiGroups), so a single Contract/Filter
+// pair can cover many deployments of the same abi.
+type Contract struct {
+	Name          string
+	Network       string
+	Address       string
+	Addresses     []string
+	Abi           string
+	ParsedAbi     abi.ABI
+	StartingBlock int64
+	Events        map[string]types.Event  // keyed by event signature (topic0)
+	Methods       map[string]types.Method // keyed by method name
+	FilterArgs    map[string]bool
+	MethodArgs    map[string]bool
+	Piping        bool
+
+	// Filters holds the log filters generated by GenerateFilters, keyed by
+	// the same event signature as Events
+	Filters map[string]Filter
+}
+
+// Init finishes constructing a Contract built from a struct literal,
+// filling in defaults that depend on other fields (e.g. Addresses falling
+// back to the single Address when the caller didn't set it)
+func (c Contract) Init() *Contract {
+	if len(c.Addresses) == 0 {
+		c.Addresses = []string{c.Address}
+	}
+	return &c
+}
+
+// ForAddress returns a copy of c scoped to a single address from its
+// group, for callers (like the Poller) that operate on one contract
+// address at a time
+func (c *Contract) ForAddress(addr string) Contract {
+	single := *c
+	single.Address = addr
+	single.Addresses = []string{addr}
+	return single
+}
+
+// GenerateFilters builds one Filter per watched event, each covering every
+// address in c.Addresses, and stores them on c.Filters. A group sharing one
+// abi therefore produces a single multi-address filter per event instead
+// of one filter per address.
+func (c *Contract) GenerateFilters() error {
+	if c.Filters == nil {
+		c.Filters = make(map[string]Filter, len(c.Events))
+	}
+	for sig, event := range c.Events {
+		c.Filters[sig] = Filter{
+			Name:      c.Name + "_" + event.Name,
+			Addresses: c.Addresses,
+			Topics:    []string{sig},
+			FromBlock: c.StartingBlock,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// etherscanAPI maps a network name to its etherscan-compatible abi lookup
+// API; the empty string is mainnet
+var etherscanAPI = map[string]string{
+	"":        "https://api.etherscan.io/api",
+	"kovan":   "https://api-kovan.etherscan.io/api",
+	"rinkeby": "https://api-rinkeby.etherscan.io/api",
+	"ropsten": "https://api-ropsten.etherscan.io/api",
+	"goerli":  "https://api-goerli.etherscan.io/api",
+}
+
+type etherscanResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  string `json:"result"`
+}
+
+// fetchAbi looks up contractAddr's abi from etherscan's getabi endpoint for
+// the given network
+func fetchAbi(network, contractAddr string) (string, error) {
+	api, ok := etherscanAPI[network]
+	if !ok {
+		return "", fmt.Errorf("parser: unsupported network %q", network)
+	}
+
+	url := fmt.Sprintf("%s?module=contract&action=getabi&address=%s", api, contractAddr)
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("error fetching abi for %s: %w", contractAddr, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading abi response for %s: %w", contractAddr, err)
+	}
+
+	var result etherscanResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("error unmarshalling abi response for %s: %w", contractAddr, err)
+	}
+	if result.Status != "1" {
+		return "", fmt.Errorf("error fetching abi for %s: %s", contractAddr, result.Message)
+	}
+
+	return result.Result, nil
+}
@@ -0,0 +1,169 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package parser fetches and parses contract abis, and extracts the
+// event/method info a Transformer needs out of them.
+package parser
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+)
+
+// Parser fetches a contract's abi (either given directly or looked up from
+// etherscan by address) and extracts watchable events
+// and methods from it
+type Parser interface {
+	Parse(contractAddr string) error
+	ParseAbiStr(abiStr string) error
+	Abi() string
+	ParsedAbi() abi.ABI
+
+	// GetEvents returns the subset of the parsed abi's events named in
+	// names; GetAllEvents returns every event the abi defines, for callers
+	// (like AutoConfigure) that want to watch everything without
+	// enumerating it
+	GetEvents(names []string) map[string]types.Event
+	GetAllEvents() map[string]types.Event
+
+	// GetSelectMethods returns the subset of the parsed abi's read-only
+	// methods named in names; GetAllSelectMethods returns every read-only
+	// method the abi defines
+	GetSelectMethods(names []string) map[string]types.Method
+	GetAllSelectMethods() map[string]types.Method
+}
+
+// parser is the concrete, network-aware implementation of Parser. Network
+// selects which etherscan-compatible API Parse looks abis up against when
+// one isn't supplied directly.
+type parser struct {
+	network   string
+	abiStr    string
+	parsedAbi abi.ABI
+}
+
+// NewParser returns a Parser that looks up abis against network ("" for
+// mainnet) when Parse is called without one already configured
+func NewParser(network string) Parser {
+	return &parser{network: network}
+}
+
+// Parse fetches contractAddr's abi from the parser network's etherscan API
+// and loads it
+func (p *parser) Parse(contractAddr string) error {
+	abiStr, err := fetchAbi(p.network, contractAddr)
+	if err != nil {
+		return err
+	}
+	return p.ParseAbiStr(abiStr)
+}
+
+// ParseAbiStr loads a raw abi JSON string
+func (p *parser) ParseAbiStr(abiStr string) error {
+	parsedAbi, err := abi.JSON(strings.NewReader(abiStr))
+	if err != nil {
+		return err
+	}
+	p.abiStr = abiStr
+	p.parsedAbi = parsedAbi
+	return nil
+}
+
+func (p *parser) Abi() string {
+	return p.abiStr
+}
+
+func (p *parser) ParsedAbi() abi.ABI {
+	return p.parsedAbi
+}
+
+func (p *parser) GetEvents(names []string) map[string]types.Event {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	events := map[string]types.Event{}
+	for name, event := range p.parsedAbi.Events {
+		if !wanted[name] {
+			continue
+		}
+		events[event.ID.Hex()] = toEvent(event)
+	}
+	return events
+}
+
+func (p *parser) GetAllEvents() map[string]types.Event {
+	events := make(map[string]types.Event, len(p.parsedAbi.Events))
+	for _, event := range p.parsedAbi.Events {
+		events[event.ID.Hex()] = toEvent(event)
+	}
+	return events
+}
+
+func (p *parser) GetSelectMethods(names []string) map[string]types.Method {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	methods := map[string]types.Method{}
+	for name, method := range p.parsedAbi.Methods {
+		if !wanted[name] || !isSelectMethod(method) {
+			continue
+		}
+		methods[name] = toMethod(method)
+	}
+	return methods
+}
+
+func (p *parser) GetAllSelectMethods() map[string]types.Method {
+	methods := map[string]types.Method{}
+	for name, method := range p.parsedAbi.Methods {
+		if !isSelectMethod(method) {
+			continue
+		}
+		methods[name] = toMethod(method)
+	}
+	return methods
+}
+
+// isSelectMethod reports whether method is read-only (a "select", in this
+// watcher's terminology) and therefore safe to poll without side effects
+func isSelectMethod(method abi.Method) bool {
+	return method.StateMutability == "view" || method.StateMutability == "pure" || method.Const
+}
+
+func toEvent(event abi.Event) types.Event {
+	fields := make([]types.Field, 0, len(event.Inputs))
+	for _, input := range event.Inputs {
+		fields = append(fields, types.Field{Name: input.Name, Type: input.Type.String(), Indexed: input.Indexed})
+	}
+	return types.Event{Name: event.Name, Sig: event.ID.Hex(), Fields: fields}
+}
+
+func toMethod(method abi.Method) types.Method {
+	args := make([]types.Field, 0, len(method.Inputs))
+	for _, input := range method.Inputs {
+		args = append(args, types.Field{Name: input.Name, Type: input.Type.String()})
+	}
+	returns := make([]types.Field, 0, len(method.Outputs))
+	for _, output := range method.Outputs {
+		returns = append(returns, types.Field{Name: output.Name, Type: output.Type.String()})
+	}
+	return types.Method{Name: method.Name, Const: isSelectMethod(method), Args: args, Returns: returns}
+}
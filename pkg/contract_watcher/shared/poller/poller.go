@@ -0,0 +1,95 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package poller calls a contract's read-only methods over an eth node and
+// hands back the decoded results. It does not persist anything itself -
+// that's left to the caller, so results can be fanned out to every
+// configured Sink instead of being tied to one postgres-only path.
+package poller
+
+import (
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/contract"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
+// Poller calls a contract's configured read-only methods and returns the
+// decoded results
+type Poller interface {
+	// FetchContractData calls method on contractAddr (passing methodArg,
+	// when given) as of lastBlock and decodes the return value into result
+	FetchContractData(abiStr, contractAddr, method string, methodArg interface{}, result interface{}, lastBlock int64) error
+
+	// PollContract calls every method configured on con as of lastBlock -
+	// once per address in con.MethodArgs for methods that take an address
+	// argument (e.g. balanceOf), once with no argument otherwise (e.g.
+	// name/symbol) - and returns the decoded results for the caller to
+	// persist
+	PollContract(con contract.Contract, lastBlock int64) ([]types.Result, error)
+}
+
+// poller is the default Poller implementation, backed directly by a
+// core.BlockChain connection
+type poller struct {
+	blockChain core.BlockChain
+	mode       types.SyncType
+}
+
+// NewPoller returns a Poller that calls contract methods over blockChain
+func NewPoller(blockChain core.BlockChain, mode types.SyncType) Poller {
+	return &poller{blockChain: blockChain, mode: mode}
+}
+
+func (p *poller) FetchContractData(abiStr, contractAddr, method string, methodArg interface{}, result interface{}, lastBlock int64) error {
+	return p.blockChain.FetchContractData(abiStr, contractAddr, method, methodArg, result, lastBlock)
+}
+
+func (p *poller) PollContract(con contract.Contract, lastBlock int64) ([]types.Result, error) {
+	var results []types.Result
+	for _, method := range con.Methods {
+		if len(method.Args) == 0 {
+			var value string
+			if err := p.FetchContractData(con.Abi, con.Address, method.Name, nil, &value, lastBlock); err != nil {
+				return nil, err
+			}
+			results = append(results, types.Result{
+				Address:     con.Address,
+				Method:      method.Name,
+				Value:       value,
+				BlockNumber: lastBlock,
+			})
+			continue
+		}
+
+		// Methods that take an argument (e.g. balanceOf(address)) are
+		// called once per configured holder address, so each result can be
+		// attributed to the address it was called with
+		for methodArg := range con.MethodArgs {
+			var value string
+			if err := p.FetchContractData(con.Abi, con.Address, method.Name, methodArg, &value, lastBlock); err != nil {
+				return nil, err
+			}
+			results = append(results, types.Result{
+				Address:     con.Address,
+				Method:      method.Name,
+				InputValues: []string{methodArg},
+				Value:       value,
+				BlockNumber: lastBlock,
+			})
+		}
+	}
+	return results, nil
+}
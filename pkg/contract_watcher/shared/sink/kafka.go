@@ -0,0 +1,91 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+)
+
+// KafkaSink publishes transformed events and method results to Kafka, one
+// topic per event signature (method results go to a single shared topic),
+// keyed by tx hash + log index so a redelivered/reprocessed log produces
+// an idempotent write under log compaction.
+type KafkaSink struct {
+	producer    sarama.SyncProducer
+	topicPrefix string
+}
+
+// NewKafkaSink dials brokers and returns a Sink that publishes to topics
+// named topicPrefix + event signature
+func NewKafkaSink(brokers []string, topicPrefix string) (*KafkaSink, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kafka producer: %w", err)
+	}
+
+	return &KafkaSink{producer: producer, topicPrefix: topicPrefix}, nil
+}
+
+func (sink *KafkaSink) PersistLogs(logs []types.Log, event types.Event, address, name string) error {
+	topic := sink.topicPrefix + event.Sig
+	for _, log := range logs {
+		payload, err := json.Marshal(log)
+		if err != nil {
+			return err
+		}
+		_, _, err = sink.producer.SendMessage(&sarama.ProducerMessage{
+			Topic: topic,
+			Key:   sarama.StringEncoder(fmt.Sprintf("%s-%d", log.TxHash, log.Index)),
+			Value: sarama.ByteEncoder(payload),
+		})
+		if err != nil {
+			return fmt.Errorf("error publishing log to kafka topic %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+func (sink *KafkaSink) PersistMethodResults(results []types.Result) error {
+	topic := sink.topicPrefix + "method_results"
+	for _, result := range results {
+		payload, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, _, err = sink.producer.SendMessage(&sarama.ProducerMessage{
+			Topic: topic,
+			Value: sarama.ByteEncoder(payload),
+		})
+		if err != nil {
+			return fmt.Errorf("error publishing method result to kafka topic %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+func (sink *KafkaSink) Close() error {
+	return sink.producer.Close()
+}
@@ -0,0 +1,94 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+)
+
+// NatsSink publishes transformed events and method results onto a NATS
+// JetStream stream, one subject per event signature (method results go to
+// a shared subject), using tx hash + log index as the Nats-Msg-Id so
+// JetStream's duplicate window makes redelivery idempotent.
+type NatsSink struct {
+	js             nats.JetStreamContext
+	conn           *nats.Conn
+	subjectPrefix  string
+	methodsSubject string
+}
+
+// NewNatsSink connects to url and returns a Sink that publishes to subjects
+// named subjectPrefix + event signature
+func NewNatsSink(url string, subjectPrefix string) (*NatsSink, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to nats: %w", err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error acquiring jetstream context: %w", err)
+	}
+
+	return &NatsSink{
+		js:             js,
+		conn:           conn,
+		subjectPrefix:  subjectPrefix,
+		methodsSubject: subjectPrefix + "method_results",
+	}, nil
+}
+
+func (sink *NatsSink) PersistLogs(logs []types.Log, event types.Event, address, name string) error {
+	subject := sink.subjectPrefix + event.Sig
+	for _, log := range logs {
+		payload, err := json.Marshal(log)
+		if err != nil {
+			return err
+		}
+		msg := nats.NewMsg(subject)
+		msg.Data = payload
+		msg.Header.Set(nats.MsgIdHdr, fmt.Sprintf("%s-%d", log.TxHash, log.Index))
+		if _, err := sink.js.PublishMsg(msg); err != nil {
+			return fmt.Errorf("error publishing log to nats subject %s: %w", subject, err)
+		}
+	}
+	return nil
+}
+
+func (sink *NatsSink) PersistMethodResults(results []types.Result) error {
+	for _, result := range results {
+		payload, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		if _, err := sink.js.Publish(sink.methodsSubject, payload); err != nil {
+			return fmt.Errorf("error publishing method result to nats subject %s: %w", sink.methodsSubject, err)
+		}
+	}
+	return nil
+}
+
+func (sink *NatsSink) Close() error {
+	sink.conn.Close()
+	return nil
+}
@@ -0,0 +1,52 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sink
+
+import (
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/repository"
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+// PostgresSink is the original Sink implementation, persisting transformed
+// events and method results into the custom postgres tables generated for
+// each watched contract
+type PostgresSink struct {
+	events  repository.EventRepository
+	methods repository.MethodDatastore
+}
+
+// NewPostgresSink wraps the existing postgres-backed event and method
+// repositories as a Sink
+func NewPostgresSink(db *postgres.DB, mode types.SyncType) *PostgresSink {
+	return &PostgresSink{
+		events:  repository.NewEventRepository(db, mode),
+		methods: repository.NewMethodDatastore(db, mode),
+	}
+}
+
+func (sink *PostgresSink) PersistLogs(logs []types.Log, event types.Event, address, name string) error {
+	return sink.events.PersistLogs(logs, event, address, name)
+}
+
+func (sink *PostgresSink) PersistMethodResults(results []types.Result) error {
+	return sink.methods.PersistResults(results)
+}
+
+func (sink *PostgresSink) Close() error {
+	return nil
+}
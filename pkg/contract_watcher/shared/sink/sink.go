@@ -0,0 +1,40 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package sink holds the destinations a Transformer can emit transformed
+// events and polled method results to. Postgres is the original, still
+// default, destination; Kafka, NATS JetStream, and a stdout JSON-lines sink
+// let the same data be fanned out to message buses and downstream tooling
+// at the same time, without the transformer caring which one it's talking
+// to.
+package sink
+
+import "github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+
+// Sink is the destination a Transformer emits transformed watched event
+// logs and polled method results to. Implementations must be safe for
+// concurrent use.
+type Sink interface {
+	// PersistLogs writes a batch of converted logs, all belonging to the
+	// same contract event, to the sink
+	PersistLogs(logs []types.Log, event types.Event, address, name string) error
+	// PersistMethodResults writes a batch of polled contract method call
+	// results to the sink
+	PersistMethodResults(results []types.Result) error
+	// Close releases any resources (connections, open files) held by the
+	// sink
+	Close() error
+}
@@ -0,0 +1,80 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sink
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/types"
+)
+
+// stdoutLogLine and stdoutMethodLine are the JSON-lines record shapes
+// written by StdoutSink, one line per log/result so downstream tooling can
+// pipe and process the stream with standard line-oriented tools
+type stdoutLogLine struct {
+	Event   string    `json:"event"`
+	Address string    `json:"address"`
+	Name    string    `json:"name"`
+	Log     types.Log `json:"log"`
+}
+
+type stdoutMethodLine struct {
+	Result types.Result `json:"result"`
+}
+
+// StdoutSink writes transformed events and method results as JSON lines to
+// an io.Writer (os.Stdout in the common case), for piping into downstream
+// tooling such as jq or a log shipper
+type StdoutSink struct {
+	mu  sync.Mutex
+	out io.Writer
+	enc *json.Encoder
+}
+
+// NewStdoutSink returns a Sink that writes one JSON object per line to out
+func NewStdoutSink(out io.Writer) *StdoutSink {
+	return &StdoutSink{out: out, enc: json.NewEncoder(out)}
+}
+
+func (sink *StdoutSink) PersistLogs(logs []types.Log, event types.Event, address, name string) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	for _, log := range logs {
+		line := stdoutLogLine{Event: event.Name, Address: address, Name: name, Log: log}
+		if err := sink.enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sink *StdoutSink) PersistMethodResults(results []types.Result) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	for _, result := range results {
+		if err := sink.enc.Encode(stdoutMethodLine{Result: result}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sink *StdoutSink) Close() error {
+	return nil
+}
@@ -0,0 +1,35 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+// Field describes a single named argument of an event or method, as pulled
+// out of a parsed contract abi
+type Field struct {
+	Name    string
+	Type    string
+	Indexed bool
+}
+
+// Event is the watcher's internal representation of a contract event,
+// derived from its abi entry. Sig is the event's topic0 (the keccak256 of
+// its canonical signature), used to key filters and to match logs to the
+// event that emitted them.
+type Event struct {
+	Name   string
+	Sig    string
+	Fields []Field
+}
@@ -0,0 +1,38 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+// SyncType identifies which sync strategy produced the data a repository or
+// sink is persisting, so implementations backed by shared storage can keep
+// full-sync and light-sync rows apart
+type SyncType int
+
+const (
+	FullSync SyncType = iota
+	LightSync
+)
+
+func (s SyncType) String() string {
+	switch s {
+	case FullSync:
+		return "full"
+	case LightSync:
+		return "light"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,26 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package datastore
+
+// CheckpointRepository tracks the last block number processed for a given
+// contract address, so that a light-sync watcher can resume from where it
+// left off after a restart instead of re-walking the chain from
+// StartingBlock every time.
+type CheckpointRepository interface {
+	GetCheckpoint(contractAddress string) (int64, error)
+	SetCheckpoint(contractAddress string, blockNumber int64) error
+}
@@ -0,0 +1,27 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package datastore
+
+import "github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/contract"
+
+// FilterRepository persists the log filters a Transformer wants a full vDB
+// sync to materialize watched event views for. CreateFilter takes the whole
+// contract.Filter, including its Addresses, so a single filter/subscription
+// can cover every address in an abi group instead of one filter per address.
+type FilterRepository interface {
+	CreateFilter(filter contract.Filter) error
+}
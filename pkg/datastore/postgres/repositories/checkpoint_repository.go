@@ -0,0 +1,57 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+// CheckpointRepository is the postgres-backed implementation of
+// datastore.CheckpointRepository. It stores the last block number
+// successfully processed for each watched contract address.
+type CheckpointRepository struct {
+	DB *postgres.DB
+}
+
+// GetCheckpoint returns the last processed block number for the given
+// contract address, or 0 if no checkpoint has been recorded yet.
+func (repository CheckpointRepository) GetCheckpoint(contractAddress string) (int64, error) {
+	var blockNumber int64
+	err := repository.DB.Get(&blockNumber,
+		`SELECT block_number FROM public.light_sync_checkpoints WHERE contract_address = $1`,
+		contractAddress)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return blockNumber, nil
+}
+
+// SetCheckpoint upserts the last processed block number for the given
+// contract address.
+func (repository CheckpointRepository) SetCheckpoint(contractAddress string, blockNumber int64) error {
+	_, err := repository.DB.Exec(
+		`INSERT INTO public.light_sync_checkpoints (contract_address, block_number)
+		VALUES ($1, $2)
+		ON CONFLICT (contract_address) DO UPDATE SET block_number = $2`,
+		contractAddress, blockNumber)
+	return err
+}
@@ -0,0 +1,42 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories
+
+import (
+	"strings"
+
+	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/contract"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+// FilterRepository is the postgres-backed implementation of
+// datastore.FilterRepository.
+type FilterRepository struct {
+	DB *postgres.DB
+}
+
+// CreateFilter stores filter as a single row, with its Addresses joined
+// into one comma-separated column, so one filter row/subscription backs an
+// entire abi group rather than one row per address.
+func (repository FilterRepository) CreateFilter(filter contract.Filter) error {
+	_, err := repository.DB.Exec(
+		`INSERT INTO public.log_filters (name, addresses, topics, from_block)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE SET addresses = $2, topics = $3, from_block = $4`,
+		filter.Name, strings.Join(filter.Addresses, ","), strings.Join(filter.Topics, ","), filter.FromBlock)
+	return err
+}
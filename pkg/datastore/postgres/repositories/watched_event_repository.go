@@ -0,0 +1,41 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories
+
+import (
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+// WatchedEventRepository is the postgres-backed implementation of
+// datastore.WatchedEventRepository.
+type WatchedEventRepository struct {
+	DB *postgres.DB
+}
+
+// GetWatchedEvents returns every row from the watched event view the named
+// filter materialized. Because FilterRepository.CreateFilter stores one
+// filter per abi group (covering every address in the group), the rows
+// returned here already span the whole group rather than a single address.
+func (repository WatchedEventRepository) GetWatchedEvents(name string) ([]*core.WatchedEvent, error) {
+	var watchedEvents []*core.WatchedEvent
+	err := repository.DB.Select(&watchedEvents,
+		`SELECT address, tx_hash, block_number, index, topic0, topic1, topic2, topic3, data
+		FROM public.watched_event_logs WHERE name = $1`,
+		name)
+	return watchedEvents, err
+}
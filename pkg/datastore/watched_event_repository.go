@@ -0,0 +1,28 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package datastore
+
+import "github.com/makerdao/vulcanizedb/pkg/core"
+
+// WatchedEventRepository reads back the watched event log views a filter
+// (created via FilterRepository.CreateFilter) produced. Because a filter
+// covering an abi group's Addresses is stored as a single row, the view it
+// names already contains logs for every address in the group, so no
+// per-address fan-out is needed here.
+type WatchedEventRepository interface {
+	GetWatchedEvents(name string) ([]*core.WatchedEvent, error)
+}